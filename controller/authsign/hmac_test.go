@@ -0,0 +1,32 @@
+package authsign
+
+import "testing"
+
+func TestSignIsDeterministic(t *testing.T) {
+	secret := []byte("s3cr3t")
+	a := Sign(secret, "GET", "/v1/backups?dest=s3%3A%2F%2Fbucket", []byte(`{}`), "1690000000")
+	b := Sign(secret, "GET", "/v1/backups?dest=s3%3A%2F%2Fbucket", []byte(`{}`), "1690000000")
+	if a != b {
+		t.Fatalf("Sign produced different output for identical input: %q vs %q", a, b)
+	}
+}
+
+func TestSignCoversEveryField(t *testing.T) {
+	secret := []byte("s3cr3t")
+	base := Sign(secret, "GET", "/v1/backups?dest=a", []byte(`{}`), "1690000000")
+
+	variants := map[string]string{
+		"method":       Sign(secret, "POST", "/v1/backups?dest=a", []byte(`{}`), "1690000000"),
+		"request URI":  Sign(secret, "GET", "/v1/backups?dest=b", []byte(`{}`), "1690000000"),
+		"path only":    Sign(secret, "GET", "/v1/backups", []byte(`{}`), "1690000000"),
+		"body":         Sign(secret, "GET", "/v1/backups?dest=a", []byte(`{"x":1}`), "1690000000"),
+		"timestamp":    Sign(secret, "GET", "/v1/backups?dest=a", []byte(`{}`), "1690000001"),
+		"secret bytes": Sign([]byte("different"), "GET", "/v1/backups?dest=a", []byte(`{}`), "1690000000"),
+	}
+
+	for field, got := range variants {
+		if got == base {
+			t.Errorf("changing %s did not change the signature", field)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package backupstore implements the storage side of jiva's backup/restore
+// feature: a BackupTarget uploads and downloads a backup's data blocks and
+// its backup.cfg manifest to a remote store, following the same
+// target/driver split as longhorn's backupstore package. S3 and NFS
+// destinations are supported; replica-side block reading and controller
+// coordination live elsewhere and build on top of this package.
+package backupstore
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// BlockChecksum identifies one block of a backup's data by its offset and
+// content checksum.
+type BlockChecksum struct {
+	Offset   int64  `json:"offset"`
+	Checksum string `json:"checksum"`
+}
+
+// Manifest is the backup.cfg written alongside a backup's data blocks. For
+// an incremental backup, ParentId names the backup it was diffed against;
+// Blocks lists every block's checksum, not just the changed ones, so the
+// next incremental backup can diff against it in turn.
+type Manifest struct {
+	Id         string          `json:"id"`
+	SnapshotId string          `json:"snapshotId"`
+	ParentId   string          `json:"parentId,omitempty"`
+	Size       int64           `json:"size"`
+	BlockSize  int64           `json:"blockSize"`
+	Blocks     []BlockChecksum `json:"blocks"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// BlockReader provides read access to a snapshot's data, one fixed-size
+// block at a time, so CreateBackup can diff against a previous backup and
+// upload only the blocks that changed. Callers typically implement this
+// over a replica's snapshot file.
+type BlockReader interface {
+	// BlockSize is the fixed size of each block returned by ReadBlock.
+	BlockSize() int64
+
+	// Size is the total size of the snapshot being backed up.
+	Size() int64
+
+	// ReadBlock returns the block of data at offset, which is always a
+	// multiple of BlockSize.
+	ReadBlock(offset int64) ([]byte, error)
+}
+
+// BackupTarget uploads and downloads the blocks and manifest that make up a
+// backup. Implementations exist for S3 (s3://bucket/prefix) and NFS
+// (nfs://host/export) destinations.
+type BackupTarget interface {
+	// URL returns the destination this target was constructed for, e.g.
+	// "s3://bucket/prefix" or "nfs://host/export".
+	URL() string
+
+	// UploadManifest writes the backup.cfg manifest for id.
+	UploadManifest(id string, manifest *Manifest) error
+
+	// DownloadManifest reads back the backup.cfg manifest for id.
+	DownloadManifest(id string) (*Manifest, error)
+
+	// UploadBlock uploads a single block's data, named by its checksum so
+	// blocks shared with earlier backups are only ever stored once.
+	UploadBlock(checksum string, data []byte) error
+
+	// DownloadBlock retrieves a previously uploaded block by checksum.
+	DownloadBlock(checksum string) ([]byte, error)
+
+	// List returns the ids of backups present at this target.
+	List() ([]string, error)
+
+	// Delete removes a backup's manifest. It does not remove blocks, since
+	// other backups' manifests may still reference them.
+	Delete(id string) error
+}
+
+// NewTarget parses a destination URL and returns the matching driver.
+func NewTarget(destURL string) (BackupTarget, error) {
+	u, err := url.Parse(destURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup target %q: %v", destURL, err)
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return newS3Target(u)
+	case "nfs":
+		return newNFSTarget(u)
+	default:
+		return nil, fmt.Errorf("unsupported backup target scheme %q", u.Scheme)
+	}
+}
+
+// DiffBlocks returns the blocks in current that are new or changed relative
+// to parent, by comparing the checksum recorded at each offset. Only these
+// blocks need to be uploaded for an incremental backup.
+func DiffBlocks(parent *Manifest, current []BlockChecksum) []BlockChecksum {
+	if parent == nil {
+		return current
+	}
+
+	parentChecksums := make(map[int64]string, len(parent.Blocks))
+	for _, b := range parent.Blocks {
+		parentChecksums[b.Offset] = b.Checksum
+	}
+
+	var changed []BlockChecksum
+	for _, b := range current {
+		if parentChecksums[b.Offset] != b.Checksum {
+			changed = append(changed, b)
+		}
+	}
+	return changed
+}
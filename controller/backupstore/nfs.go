@@ -0,0 +1,97 @@
+package backupstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// nfsTarget stores backups under an NFS export, addressed as
+// nfs://host/export. jiva expects the export to already be mounted on the
+// host (by the operator or an init container) rather than managing the
+// mount itself.
+type nfsTarget struct {
+	url       string
+	mountPath string
+}
+
+func newNFSTarget(u *url.URL) (*nfsTarget, error) {
+	mountPath, err := resolveNFSMount(u.Host, u.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &nfsTarget{url: u.String(), mountPath: mountPath}, nil
+}
+
+// resolveNFSMount finds where host:export is already mounted on this host.
+func resolveNFSMount(host, export string) (string, error) {
+	mountPath := filepath.Join("/var/lib/jiva/nfs", host, export)
+	if _, err := os.Stat(mountPath); err != nil {
+		return "", fmt.Errorf("nfs export %s:%s not mounted at %s: %v", host, export, mountPath, err)
+	}
+	return mountPath, nil
+}
+
+func (t *nfsTarget) URL() string { return t.url }
+
+func (t *nfsTarget) path(parts ...string) string {
+	return filepath.Join(append([]string{t.mountPath}, parts...)...)
+}
+
+func (t *nfsTarget) UploadManifest(id string, manifest *Manifest) error {
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	dir := t.path(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "backup.cfg"), b, 0644)
+}
+
+func (t *nfsTarget) DownloadManifest(id string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(t.path(id, "backup.cfg"))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (t *nfsTarget) UploadBlock(checksum string, data []byte) error {
+	dir := t.path("blocks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, checksum), data, 0644)
+}
+
+func (t *nfsTarget) DownloadBlock(checksum string) ([]byte, error) {
+	return ioutil.ReadFile(t.path("blocks", checksum))
+}
+
+func (t *nfsTarget) List() ([]string, error) {
+	entries, err := ioutil.ReadDir(t.mountPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "blocks" {
+			ids = append(ids, e.Name())
+		}
+	}
+	return ids, nil
+}
+
+func (t *nfsTarget) Delete(id string) error {
+	return os.RemoveAll(t.path(id))
+}
@@ -0,0 +1,131 @@
+package backupstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3Target stores backups under s3://bucket/prefix. Credentials come from
+// the environment (AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY/AWS_REGION) or
+// whatever the default AWS credential chain resolves, same as the AWS CLI.
+type s3Target struct {
+	url    string
+	bucket string
+	prefix string
+	client *s3.S3
+}
+
+func newS3Target(u *url.URL) (*s3Target, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	return &s3Target{
+		url:    u.String(),
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+		client: s3.New(sess),
+	}, nil
+}
+
+func (t *s3Target) URL() string { return t.url }
+
+func (t *s3Target) key(parts ...string) string {
+	return strings.Join(append([]string{t.prefix}, parts...), "/")
+}
+
+func (t *s3Target) UploadManifest(id string, manifest *Manifest) error {
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	_, err = t.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(id, "backup.cfg")),
+		Body:   bytes.NewReader(b),
+	})
+	return err
+}
+
+func (t *s3Target) DownloadManifest(id string) (*Manifest, error) {
+	out, err := t.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(id, "backup.cfg")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	content, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (t *s3Target) UploadBlock(checksum string, data []byte) error {
+	_, err := t.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key("blocks", checksum)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (t *s3Target) DownloadBlock(checksum string) ([]byte, error) {
+	out, err := t.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key("blocks", checksum)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (t *s3Target) List() ([]string, error) {
+	out, err := t.client.ListObjects(&s3.ListObjectsInput{
+		Bucket: aws.String(t.bucket),
+		Prefix: aws.String(t.prefix + "/"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, obj := range out.Contents {
+		rel := strings.TrimPrefix(aws.StringValue(obj.Key), t.prefix+"/")
+		parts := strings.SplitN(rel, "/", 2)
+		if len(parts) == 2 && parts[1] == "backup.cfg" {
+			seen[parts[0]] = true
+		}
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (t *s3Target) Delete(id string) error {
+	_, err := t.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(id, "backup.cfg")),
+	})
+	return err
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// AuthProvider supplies per-request credentials for a ControllerClient. It
+// is consulted for every outgoing request once WithAuth has been called.
+type AuthProvider interface {
+	// Authenticate sets whatever headers req needs to authenticate against
+	// the controller. body is the exact bytes that will be sent, so
+	// providers that sign the request (like HMACAuthProvider) can cover it.
+	Authenticate(req *http.Request, body []byte) error
+}
+
+// RefreshingAuthProvider is implemented by providers that can obtain new
+// credentials after a 401, such as one backed by a short-lived token. do()
+// calls Refresh at most once per request before giving up.
+type RefreshingAuthProvider interface {
+	AuthProvider
+	Refresh(ctx context.Context) error
+}
+
+// WithAuth attaches provider to the client; every request made afterwards
+// is authenticated through it.
+func (c *ControllerClient) WithAuth(provider AuthProvider) *ControllerClient {
+	c.authProvider = provider
+	return c
+}
+
+// WithTLSConfig sets the client's transport TLS config, used for mTLS to
+// the controller (client certificate in tlsConfig.Certificates, controller
+// CA in tlsConfig.RootCAs).
+func (c *ControllerClient) WithTLSConfig(tlsConfig *tls.Config) *ControllerClient {
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok || transport == nil {
+		transport = &http.Transport{}
+	}
+	transport.TLSClientConfig = tlsConfig
+	c.httpClient.Transport = transport
+	return c
+}
+
+// send issues a request through sendWithRetry and, on a 401 from a
+// RefreshingAuthProvider, refreshes credentials once and retries.
+func (c *ControllerClient) send(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	httpResp, err := c.sendWithRetry(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusUnauthorized {
+		return httpResp, nil
+	}
+
+	refresher, ok := c.authProvider.(RefreshingAuthProvider)
+	if !ok {
+		return httpResp, nil
+	}
+	httpResp.Body.Close()
+
+	if err := refresher.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("refreshing credentials after 401: %v", err)
+	}
+	return c.sendWithRetry(ctx, method, url, body)
+}
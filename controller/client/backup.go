@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/openebs/jiva/controller/backupstore"
+	"github.com/openebs/jiva/controller/rest"
+)
+
+// CreateBackup registers a backup of snapshot with the controller, then
+// uploads it to dest. If parentID names a previous backup at dest, only
+// the blocks that changed since that backup are uploaded; pass "" for a
+// full backup.
+func (c *ControllerClient) CreateBackup(snapshot string, src backupstore.BlockReader, dest backupstore.BackupTarget, parentID string) (*rest.BackupStatus, error) {
+	return c.CreateBackupContext(context.Background(), snapshot, src, dest, parentID)
+}
+
+func (c *ControllerClient) CreateBackupContext(ctx context.Context, snapshot string, src backupstore.BlockReader, dest backupstore.BackupTarget, parentID string) (*rest.BackupStatus, error) {
+	output := &rest.BackupStatus{}
+	if err := c.post(ctx, "/backups", &rest.BackupInput{
+		Name: snapshot,
+		Dest: dest.URL(),
+	}, output); err != nil {
+		return output, err
+	}
+
+	var parent *backupstore.Manifest
+	if parentID != "" {
+		p, err := dest.DownloadManifest(parentID)
+		if err != nil {
+			return output, fmt.Errorf("load parent backup %s: %v", parentID, err)
+		}
+		parent = p
+	}
+
+	manifest, blocks, err := readManifest(output.Id, snapshot, parentID, src)
+	if err != nil {
+		return output, err
+	}
+
+	for _, b := range backupstore.DiffBlocks(parent, manifest.Blocks) {
+		if err := dest.UploadBlock(b.Checksum, blocks[b.Offset]); err != nil {
+			return output, fmt.Errorf("upload block at offset %d: %v", b.Offset, err)
+		}
+	}
+
+	if err := dest.UploadManifest(manifest.Id, manifest); err != nil {
+		return output, fmt.Errorf("upload manifest: %v", err)
+	}
+
+	return output, nil
+}
+
+// readManifest reads every block of src, building the manifest that
+// describes the backup and an offset-keyed cache of the block data read
+// along the way, so the caller doesn't have to read changed blocks twice.
+func readManifest(id, snapshot, parentID string, src backupstore.BlockReader) (*backupstore.Manifest, map[int64][]byte, error) {
+	blockSize := src.BlockSize()
+	size := src.Size()
+
+	blocks := make([]backupstore.BlockChecksum, 0, (size+blockSize-1)/blockSize)
+	data := make(map[int64][]byte, cap(blocks))
+	for offset := int64(0); offset < size; offset += blockSize {
+		b, err := src.ReadBlock(offset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read block at offset %d: %v", offset, err)
+		}
+		sum := sha256.Sum256(b)
+		blocks = append(blocks, backupstore.BlockChecksum{Offset: offset, Checksum: hex.EncodeToString(sum[:])})
+		data[offset] = b
+	}
+
+	manifest := &backupstore.Manifest{
+		Id:         id,
+		SnapshotId: snapshot,
+		ParentId:   parentID,
+		Size:       size,
+		BlockSize:  blockSize,
+		Blocks:     blocks,
+		CreatedAt:  time.Now().UTC(),
+	}
+	return manifest, data, nil
+}
+
+func (c *ControllerClient) ListBackups(dest backupstore.BackupTarget) ([]rest.BackupStatus, error) {
+	return c.ListBackupsContext(context.Background(), dest)
+}
+
+func (c *ControllerClient) ListBackupsContext(ctx context.Context, dest backupstore.BackupTarget) ([]rest.BackupStatus, error) {
+	var resp rest.BackupCollection
+	err := c.get(ctx, "/backups?dest="+url.QueryEscape(dest.URL()), &resp)
+	return resp.Data, err
+}
+
+func (c *ControllerClient) RestoreBackup(backupURL string) error {
+	return c.RestoreBackupContext(context.Background(), backupURL)
+}
+
+func (c *ControllerClient) RestoreBackupContext(ctx context.Context, backupURL string) error {
+	return c.post(ctx, "/backups/"+url.QueryEscape(backupURL)+"/restore", &rest.RestoreInput{
+		BackupURL: backupURL,
+	}, nil)
+}
+
+func (c *ControllerClient) GetBackupStatus(id string) (*rest.BackupStatus, error) {
+	return c.GetBackupStatusContext(context.Background(), id)
+}
+
+func (c *ControllerClient) GetBackupStatusContext(ctx context.Context, id string) (*rest.BackupStatus, error) {
+	output := &rest.BackupStatus{}
+	err := c.get(ctx, "/backups/"+id, output)
+	return output, err
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openebs/jiva/controller/backupstore"
+	"github.com/openebs/jiva/controller/rest"
+)
+
+// memBlockReader is a BlockReader backed by an in-memory byte slice, for
+// exercising CreateBackup without a real replica.
+type memBlockReader struct {
+	blockSize int64
+	data      []byte
+}
+
+func (r *memBlockReader) BlockSize() int64 { return r.blockSize }
+func (r *memBlockReader) Size() int64      { return int64(len(r.data)) }
+
+func (r *memBlockReader) ReadBlock(offset int64) ([]byte, error) {
+	end := offset + r.blockSize
+	if end > int64(len(r.data)) {
+		end = int64(len(r.data))
+	}
+	return r.data[offset:end], nil
+}
+
+// memBackupTarget is a BackupTarget backed by in-memory maps, for
+// exercising CreateBackup without a real S3 or NFS store.
+type memBackupTarget struct {
+	mu        sync.Mutex
+	manifests map[string]*backupstore.Manifest
+	blocks    map[string][]byte
+}
+
+func newMemBackupTarget() *memBackupTarget {
+	return &memBackupTarget{
+		manifests: make(map[string]*backupstore.Manifest),
+		blocks:    make(map[string][]byte),
+	}
+}
+
+func (t *memBackupTarget) URL() string { return "mem://test" }
+
+func (t *memBackupTarget) UploadManifest(id string, manifest *backupstore.Manifest) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.manifests[id] = manifest
+	return nil
+}
+
+func (t *memBackupTarget) DownloadManifest(id string) (*backupstore.Manifest, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.manifests[id], nil
+}
+
+func (t *memBackupTarget) UploadBlock(checksum string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	t.blocks[checksum] = cp
+	return nil
+}
+
+func (t *memBackupTarget) DownloadBlock(checksum string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.blocks[checksum], nil
+}
+
+func (t *memBackupTarget) List() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]string, 0, len(t.manifests))
+	for id := range t.manifests {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (t *memBackupTarget) Delete(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.manifests, id)
+	return nil
+}
+
+// newMockControllerServer returns a mock /backups POST endpoint that
+// assigns each backup a sequential id, and the client pointed at it.
+func newMockControllerServer(t *testing.T) (*ControllerClient, func()) {
+	t.Helper()
+
+	var nextID int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" || req.URL.Path != "/v1/backups" {
+			http.NotFound(w, req)
+			return
+		}
+		var in rest.BackupInput
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		nextID++
+		out := rest.BackupStatus{
+			Id:         fmt.Sprintf("backup-%d", nextID),
+			SnapshotId: in.Name,
+			Dest:       in.Dest,
+			State:      "done",
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+
+	client := NewControllerClient(srv.URL)
+	return client, srv.Close
+}
+
+func TestCreateBackupFullUploadsEveryBlock(t *testing.T) {
+	client, closeSrv := newMockControllerServer(t)
+	defer closeSrv()
+
+	dest := newMemBackupTarget()
+	src := &memBlockReader{blockSize: 4, data: []byte("aaaabbbbcccc")}
+
+	status, err := client.CreateBackup("snap-1", src, dest, "")
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	if status.Id == "" {
+		t.Fatalf("expected controller to assign a backup id")
+	}
+
+	manifest, err := dest.DownloadManifest(status.Id)
+	if err != nil || manifest == nil {
+		t.Fatalf("expected manifest to be uploaded, err=%v", err)
+	}
+	if len(manifest.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks in manifest, got %d", len(manifest.Blocks))
+	}
+	for _, b := range manifest.Blocks {
+		if _, ok := dest.blocks[b.Checksum]; !ok {
+			t.Errorf("block at offset %d was not uploaded", b.Offset)
+		}
+	}
+}
+
+func TestCreateBackupIncrementalUploadsOnlyChangedBlocks(t *testing.T) {
+	client, closeSrv := newMockControllerServer(t)
+	defer closeSrv()
+
+	dest := newMemBackupTarget()
+	parentSrc := &memBlockReader{blockSize: 4, data: []byte("aaaabbbbcccc")}
+
+	parentStatus, err := client.CreateBackup("snap-1", parentSrc, dest, "")
+	if err != nil {
+		t.Fatalf("CreateBackup (parent): %v", err)
+	}
+	uploadedAfterFull := len(dest.blocks)
+
+	childSrc := &memBlockReader{blockSize: 4, data: []byte("aaaaXXXXcccc")}
+	childStatus, err := client.CreateBackup("snap-2", childSrc, dest, parentStatus.Id)
+	if err != nil {
+		t.Fatalf("CreateBackup (incremental): %v", err)
+	}
+
+	manifest, err := dest.DownloadManifest(childStatus.Id)
+	if err != nil || manifest == nil {
+		t.Fatalf("expected child manifest to be uploaded, err=%v", err)
+	}
+	if manifest.ParentId != parentStatus.Id {
+		t.Errorf("expected manifest.ParentId %q, got %q", parentStatus.Id, manifest.ParentId)
+	}
+
+	if got := len(dest.blocks); got != uploadedAfterFull+1 {
+		t.Errorf("expected exactly 1 new block uploaded for the incremental backup, blocks went from %d to %d", uploadedAfterFull, got)
+	}
+}
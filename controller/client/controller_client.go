@@ -2,46 +2,120 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/openebs/jiva/controller/rest"
 	"github.com/openebs/jiva/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	defaultConnectTimeout = 5 * time.Second
+	defaultRequestTimeout = 30 * time.Second
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 100 * time.Millisecond
+)
+
+// controllerEndpoint tracks one controller's base URL and whether it is
+// currently believed reachable (cleared on a connection error or 5xx, set
+// again only by the background probe), so a client backed by several
+// controllers can skip the ones it currently believes are down.
+type controllerEndpoint struct {
+	base    string
+	healthy bool
+}
+
 type ControllerClient struct {
-	controller string
+	mu             sync.Mutex
+	endpoints      []*controllerEndpoint
+	policy         Policy
+	httpClient     *http.Client
+	streamClient   *http.Client
+	maxRetries     int
+	retryBaseDelay time.Duration
+	probeInterval  time.Duration
+	stopProbe      chan struct{}
+	authProvider   AuthProvider
+	metrics        *metrics
+	tracer         trace.Tracer
+	propagator     propagation.TextMapPropagator
 }
 
 func NewControllerClient(controller string) *ControllerClient {
+	httpClient := defaultHTTPClient()
+	return &ControllerClient{
+		endpoints:      []*controllerEndpoint{{base: normalizeControllerURL(controller), healthy: true}},
+		policy:         PolicySequential,
+		httpClient:     httpClient,
+		streamClient:   streamHTTPClient(httpClient.Transport),
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		metrics:        defaultClientMetrics(),
+		tracer:         defaultTracer(),
+		propagator:     defaultPropagator(),
+	}
+}
+
+func normalizeControllerURL(controller string) string {
 	if !strings.HasSuffix(controller, "/v1") {
 		controller += "/v1"
 	}
-	return &ControllerClient{
-		controller: controller,
+	return controller
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: defaultRequestTimeout,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: defaultConnectTimeout,
+			}).DialContext,
+		},
 	}
 }
 
+// streamHTTPClient builds the client used for long-lived SSE connections
+// (TailJournal). It shares the regular client's transport and dial timeout
+// but has no overall request timeout, since a streaming response body is
+// expected to stay open for as long as the caller's context allows.
+func streamHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: transport}
+}
+
 func (c *ControllerClient) Start(replicas ...string) error {
-	volume, err := c.GetVolume()
+	return c.StartContext(context.Background(), replicas...)
+}
+
+func (c *ControllerClient) StartContext(ctx context.Context, replicas ...string) error {
+	volume, err := c.GetVolumeContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	return c.post(volume.Actions["start"], rest.StartInput{
+	return c.post(ctx, volume.Actions["start"], rest.StartInput{
 		Replicas: replicas,
 	}, nil)
 }
 
 func (c *ControllerClient) RevertVolume(name string) (*rest.Volume, error) {
-	volume, err := c.GetVolume()
+	return c.RevertVolumeContext(context.Background(), name)
+}
+
+func (c *ControllerClient) RevertVolumeContext(ctx context.Context, name string) (*rest.Volume, error) {
+	volume, err := c.GetVolumeContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +126,7 @@ func (c *ControllerClient) RevertVolume(name string) (*rest.Volume, error) {
 
 	output := &rest.Volume{}
 
-	err = c.post(volume.Actions["revert"], input, output)
+	err = c.post(ctx, volume.Actions["revert"], input, output)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +135,11 @@ func (c *ControllerClient) RevertVolume(name string) (*rest.Volume, error) {
 }
 
 func (c *ControllerClient) Snapshot(name string) (string, error) {
-	volume, err := c.GetVolume()
+	return c.SnapshotContext(context.Background(), name)
+}
+
+func (c *ControllerClient) SnapshotContext(ctx context.Context, name string) (string, error) {
+	volume, err := c.GetVolumeContext(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -70,7 +148,7 @@ func (c *ControllerClient) Snapshot(name string) (string, error) {
 		Name: name,
 	}
 	output := &rest.SnapshotOutput{}
-	err = c.post(volume.Actions["snapshot"], input, output)
+	err = c.post(ctx, volume.Actions["snapshot"], input, output)
 	if err != nil {
 		return "", err
 	}
@@ -79,63 +157,77 @@ func (c *ControllerClient) Snapshot(name string) (string, error) {
 }
 
 func (c *ControllerClient) RevertSnapshot(snapshot string) error {
-	volume, err := c.GetVolume()
+	return c.RevertSnapshotContext(context.Background(), snapshot)
+}
+
+func (c *ControllerClient) RevertSnapshotContext(ctx context.Context, snapshot string) error {
+	volume, err := c.GetVolumeContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	return c.post(volume.Actions["revert"], rest.RevertInput{
+	return c.post(ctx, volume.Actions["revert"], rest.RevertInput{
 		Name: snapshot,
 	}, nil)
 }
 
 func (c *ControllerClient) ListJournal(limit int) error {
-	err := c.post("/journal", &rest.JournalInput{Limit: limit}, nil)
-	return err
+	return c.ListJournalContext(context.Background(), limit)
+}
+
+func (c *ControllerClient) ListJournalContext(ctx context.Context, limit int) error {
+	return c.post(ctx, "/journal", &rest.JournalInput{Limit: limit}, nil)
 }
 
 func (c *ControllerClient) ListReplicas() ([]rest.Replica, error) {
+	return c.ListReplicasContext(context.Background())
+}
+
+func (c *ControllerClient) ListReplicasContext(ctx context.Context) ([]rest.Replica, error) {
 	var resp rest.ReplicaCollection
-	err := c.get("/replicas", &resp)
+	err := c.get(ctx, "/replicas", &resp)
 	return resp.Data, err
 }
 
 func (c *ControllerClient) CreateReplica(address string) (*rest.Replica, error) {
+	return c.CreateReplicaContext(context.Background(), address)
+}
+
+func (c *ControllerClient) CreateReplicaContext(ctx context.Context, address string) (*rest.Replica, error) {
 	var resp rest.Replica
-	err := c.post("/replicas", &rest.Replica{
+	err := c.post(ctx, "/replicas", &rest.Replica{
 		Address: address,
 	}, &resp)
 	return &resp, err
 }
 
 func (c *ControllerClient) CreateQuorumReplica(address string) (*rest.Replica, error) {
+	return c.CreateQuorumReplicaContext(context.Background(), address)
+}
+
+func (c *ControllerClient) CreateQuorumReplicaContext(ctx context.Context, address string) (*rest.Replica, error) {
 	var resp rest.Replica
-	err := c.post("/quorumreplicas", &rest.Replica{
+	err := c.post(ctx, "/quorumreplicas", &rest.Replica{
 		Address: address,
 	}, &resp)
 	return &resp, err
 }
 
 func (c *ControllerClient) DeleteReplica(address string) (*rest.Replica, error) {
-	reps, err := c.ListReplicas()
+	return c.DeleteReplicaContext(context.Background(), address)
+}
+
+func (c *ControllerClient) DeleteReplicaContext(ctx context.Context, address string) (*rest.Replica, error) {
+	reps, err := c.ListReplicasContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, rep := range reps {
 		if rep.Address == address {
-			httpReq, err := http.NewRequest("DELETE", rep.Links["self"], nil)
-			if err != nil {
+			if err := c.do(ctx, "DELETE", rep.Links["self"], nil, nil); err != nil {
 				return nil, err
 			}
-			httpResp, err := http.DefaultClient.Do(httpReq)
-			if err != nil {
-				return nil, err
-			}
-			if httpResp.StatusCode >= 300 {
-				content, _ := ioutil.ReadAll(httpResp.Body)
-				return nil, fmt.Errorf("Bad response: %d %s: %s", httpResp.StatusCode, httpResp.Status, content)
-			}
 			return &rep, nil
 		}
 	}
@@ -144,41 +236,61 @@ func (c *ControllerClient) DeleteReplica(address string) (*rest.Replica, error)
 }
 
 func (c *ControllerClient) UpdateReplica(replica rest.Replica) (rest.Replica, error) {
+	return c.UpdateReplicaContext(context.Background(), replica)
+}
+
+func (c *ControllerClient) UpdateReplicaContext(ctx context.Context, replica rest.Replica) (rest.Replica, error) {
 	var resp rest.Replica
-	err := c.put(replica.Links["self"], &replica, &resp)
+	err := c.put(ctx, replica.Links["self"], &replica, &resp)
 	return resp, err
 }
 
 func (c *ControllerClient) GetReplica(address string) (*rest.Replica, error) {
+	return c.GetReplicaContext(context.Background(), address)
+}
+
+func (c *ControllerClient) GetReplicaContext(ctx context.Context, address string) (*rest.Replica, error) {
 	resp := &rest.Replica{}
-	err := c.get("/replicas/"+address, &resp)
+	err := c.get(ctx, "/replicas/"+address, &resp)
 	return resp, err
 }
 
 func (c *ControllerClient) VerifyRebuildReplica(address string) error {
-	replica, err := c.GetReplica(address)
+	return c.VerifyRebuildReplicaContext(context.Background(), address)
+}
+
+func (c *ControllerClient) VerifyRebuildReplicaContext(ctx context.Context, address string) error {
+	replica, err := c.GetReplicaContext(ctx, address)
 	if err != nil {
 		logrus.Errorf("getReplica in verifyRebuildReplica failed %s", address)
 		return err
 	}
-	return c.post(replica.Actions["verifyrebuild"], &replica, nil)
+	return c.post(ctx, replica.Actions["verifyrebuild"], &replica, nil)
 }
 
 func (c *ControllerClient) PrepareRebuild(address string) (*rest.PrepareRebuildOutput, error) {
+	return c.PrepareRebuildContext(context.Background(), address)
+}
+
+func (c *ControllerClient) PrepareRebuildContext(ctx context.Context, address string) (*rest.PrepareRebuildOutput, error) {
 	var output rest.PrepareRebuildOutput
-	replica, err := c.GetReplica(address)
+	replica, err := c.GetReplicaContext(ctx, address)
 	if err != nil {
 		logrus.Errorf("getReplica in prepareRebuild failed %s", address)
 		return nil, err
 	}
-	err = c.post(replica.Actions["preparerebuild"], &replica, &output)
+	err = c.post(ctx, replica.Actions["preparerebuild"], &replica, &output)
 	return &output, err
 }
 
 func (c *ControllerClient) GetVolume() (*rest.Volume, error) {
+	return c.GetVolumeContext(context.Background())
+}
+
+func (c *ControllerClient) GetVolumeContext(ctx context.Context) (*rest.Volume, error) {
 	var volumes rest.VolumeCollection
 
-	err := c.get("/volumes", &volumes)
+	err := c.get(ctx, "/volumes", &volumes)
 	if err != nil {
 		logrus.Errorf("GetVolume failed, %v", err)
 		return nil, err
@@ -193,7 +305,11 @@ func (c *ControllerClient) GetVolume() (*rest.Volume, error) {
 }
 
 func (c *ControllerClient) Register(address string, revisionCount int64, peerDetails types.PeerDetails, replicaType string, upTime time.Duration, state string) error {
-	err := c.post("/register", &rest.RegReplica{
+	return c.RegisterContext(context.Background(), address, revisionCount, peerDetails, replicaType, upTime, state)
+}
+
+func (c *ControllerClient) RegisterContext(ctx context.Context, address string, revisionCount int64, peerDetails types.PeerDetails, replicaType string, upTime time.Duration, state string) error {
+	return c.post(ctx, "/register", &rest.RegReplica{
 		Address:     address,
 		RevCount:    strconv.FormatInt(revisionCount, 10),
 		PeerDetails: peerDetails,
@@ -201,60 +317,262 @@ func (c *ControllerClient) Register(address string, revisionCount int64, peerDet
 		UpTime:      upTime,
 		RepState:    state,
 	}, nil)
-	return err
 }
 
-func (c *ControllerClient) post(path string, req, resp interface{}) error {
-	return c.do("POST", path, req, resp)
+func (c *ControllerClient) post(ctx context.Context, path string, req, resp interface{}) error {
+	return c.do(ctx, "POST", path, req, resp)
 }
 
-func (c *ControllerClient) put(path string, req, resp interface{}) error {
-	return c.do("PUT", path, req, resp)
+func (c *ControllerClient) put(ctx context.Context, path string, req, resp interface{}) error {
+	return c.do(ctx, "PUT", path, req, resp)
 }
 
-func (c *ControllerClient) do(method, path string, req, resp interface{}) error {
-	b, err := json.Marshal(req)
-	if err != nil {
-		return err
+func (c *ControllerClient) get(ctx context.Context, path string, obj interface{}) error {
+	return c.do(ctx, "GET", path, nil, obj)
+}
+
+// retryableMethod reports whether method is safe to retry automatically:
+// GET and PUT against replicas, and registration POSTs, are idempotent from
+// the controller's point of view, so a connection error or 5xx on them can
+// be retried with backoff instead of bubbling straight up to the caller.
+func retryableMethod(method, path string) bool {
+	switch method {
+	case "GET", "PUT":
+		return true
+	case "POST":
+		return strings.HasSuffix(path, "/register")
+	default:
+		return false
 	}
+}
 
-	bodyType := "application/json"
-	url := path
-	if !strings.HasPrefix(url, "http") {
-		url = c.controller + path
+func (c *ControllerClient) do(ctx context.Context, method, path string, req, resp interface{}) error {
+	var body []byte
+	if req != nil {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = b
 	}
 
-	logrus.Debugf("%s %s", method, url)
-	httpReq, err := http.NewRequest(method, url, bytes.NewBuffer(b))
-	if err != nil {
+	ctx, span, label := c.startSpan(ctx, method, path)
+	defer span.End()
+	start := time.Now()
+
+	// Paths that already carry a full URL (e.g. a replica's "self" link)
+	// name a specific host and bypass endpoint selection entirely.
+	if strings.HasPrefix(path, "http") {
+		logrus.Debugf("%s %s", method, path)
+		httpResp, err := c.send(ctx, method, path, body)
+		if err != nil {
+			finishSpanError(span, "controller.unreachable", err)
+			c.recordMetrics(method, label, "error", start)
+			return &ControllerError{Endpoint: path, Err: err}
+		}
+		status, err := decodeResponse(span, path, httpResp, resp)
+		c.recordMetrics(method, label, status, start)
 		return err
 	}
-	httpReq.Header.Set("Content-Type", bodyType)
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
+	candidates := c.candidateEndpoints()
+	if len(candidates) == 0 {
+		err := errors.New("no controller endpoints configured")
+		finishSpanError(span, "controller.unreachable", err)
+		c.recordMetrics(method, label, "error", start)
 		return err
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode >= 300 {
-		content, _ := ioutil.ReadAll(httpResp.Body)
-		return fmt.Errorf("Bad response: %d %s: %s", httpResp.StatusCode, httpResp.Status, content)
+	if c.policy == PolicyParallelRead && method == "GET" && len(candidates) > 1 {
+		return c.doParallelRead(ctx, span, label, path, body, resp, candidates, start)
 	}
 
-	if resp == nil {
-		return nil
+	// Failing over a non-idempotent write (e.g. Snapshot, CreateReplica,
+	// CreateBackup) to the next endpoint risks resending a request a
+	// controller already committed before erroring, so only retryable
+	// methods keep trying further endpoints past a connection error or 5xx.
+	canFailover := retryableMethod(method, path)
+
+	var merr MultiError
+	for _, ep := range candidates {
+		url := ep.base + path
+		logrus.Debugf("%s %s", method, url)
+
+		httpResp, err := c.send(ctx, method, url, body)
+		if err != nil {
+			c.setHealthy(ep, false)
+			merr = append(merr, &ControllerError{Endpoint: ep.base, Err: err})
+			if !canFailover {
+				break
+			}
+			continue
+		}
+		if httpResp.StatusCode >= 500 {
+			content, _ := ioutil.ReadAll(httpResp.Body)
+			httpResp.Body.Close()
+			c.setHealthy(ep, false)
+			merr = append(merr, &ControllerError{Endpoint: ep.base, StatusCode: httpResp.StatusCode, Body: content})
+			if !canFailover {
+				break
+			}
+			continue
+		}
+
+		status, err := decodeResponse(span, ep.base, httpResp, resp)
+		c.recordMetrics(method, label, status, start)
+		return err
 	}
 
-	return json.NewDecoder(httpResp.Body).Decode(resp)
+	err := merr.orNil()
+	finishSpanError(span, "controller.unreachable", err)
+	c.recordMetrics(method, label, "error", start)
+	return err
 }
 
-func (c *ControllerClient) get(path string, obj interface{}) error {
-	resp, err := http.Get(c.controller + path)
-	if err != nil {
+// doParallelRead races a GET against every candidate endpoint and returns
+// the first successful response, used for read-only calls under
+// PolicyParallelRead so a slow or unreachable controller doesn't add
+// latency to the common case.
+func (c *ControllerClient) doParallelRead(ctx context.Context, span trace.Span, label, path string, body []byte, resp interface{}, candidates []*controllerEndpoint, start time.Time) error {
+	type result struct {
+		ep       *controllerEndpoint
+		httpResp *http.Response
+		err      error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, len(candidates))
+	for _, ep := range candidates {
+		go func(ep *controllerEndpoint) {
+			httpResp, err := c.send(ctx, "GET", ep.base+path, body)
+			results <- result{ep: ep, httpResp: httpResp, err: err}
+		}(ep)
+	}
+
+	var merr MultiError
+	for i := 0; i < len(candidates); i++ {
+		r := <-results
+		if r.err != nil {
+			c.setHealthy(r.ep, false)
+			merr = append(merr, &ControllerError{Endpoint: r.ep.base, Err: r.err})
+			continue
+		}
+		if r.httpResp.StatusCode >= 500 {
+			content, _ := ioutil.ReadAll(r.httpResp.Body)
+			r.httpResp.Body.Close()
+			c.setHealthy(r.ep, false)
+			merr = append(merr, &ControllerError{Endpoint: r.ep.base, StatusCode: r.httpResp.StatusCode, Body: content})
+			continue
+		}
+
+		cancel()
+		status, err := decodeResponse(span, r.ep.base, r.httpResp, resp)
+		c.recordMetrics("GET", label, status, start)
+
+		// Other candidates may still be in flight; drain their responses so
+		// none of the extra httpResp.Body values leak a connection.
+		remaining := len(candidates) - i - 1
+		go func() {
+			for j := 0; j < remaining; j++ {
+				if late := <-results; late.httpResp != nil {
+					late.httpResp.Body.Close()
+				}
+			}
+		}()
 		return err
 	}
-	defer resp.Body.Close()
 
-	return json.NewDecoder(resp.Body).Decode(obj)
+	err := merr.orNil()
+	finishSpanError(span, "controller.unreachable", err)
+	c.recordMetrics("GET", label, "error", start)
+	return err
+}
+
+// candidateEndpoints returns the endpoints believed healthy. If none are
+// currently healthy it falls back to trying all of them, so a fully down
+// set of controllers is still attempted rather than failing closed forever.
+func (c *ControllerClient) candidateEndpoints() []*controllerEndpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	healthy := make([]*controllerEndpoint, 0, len(c.endpoints))
+	for _, ep := range c.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		return append([]*controllerEndpoint(nil), c.endpoints...)
+	}
+	return healthy
+}
+
+func (c *ControllerClient) setHealthy(ep *controllerEndpoint, healthy bool) {
+	c.mu.Lock()
+	ep.healthy = healthy
+	c.mu.Unlock()
+}
+
+// sendWithRetry issues the request, retrying idempotent methods with
+// exponential backoff on connection errors or 5xx responses. It gives up
+// early, without consuming a retry, if ctx is done.
+func (c *ControllerClient) sendWithRetry(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	span := trace.SpanFromContext(ctx)
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+			delay := c.retryBaseDelay * time.Duration(1<<uint(attempt-1))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+
+		httpReq, err := http.NewRequest(method, url, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		httpReq = httpReq.WithContext(ctx)
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		if c.propagator != nil {
+			c.propagator.Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+		}
+		if c.authProvider != nil {
+			if err := c.authProvider.Authenticate(httpReq, body); err != nil {
+				return nil, err
+			}
+		}
+
+		httpResp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			lastErr = err
+			if attempt == c.maxRetries || !retryableMethod(method, url) {
+				return nil, err
+			}
+			continue
+		}
+
+		if httpResp.StatusCode >= 500 && attempt < c.maxRetries && retryableMethod(method, url) {
+			content, _ := ioutil.ReadAll(httpResp.Body)
+			httpResp.Body.Close()
+			lastErr = &ControllerError{Endpoint: url, StatusCode: httpResp.StatusCode, Body: content}
+			continue
+		}
+
+		return httpResp, nil
+	}
+
+	return nil, lastErr
 }
@@ -0,0 +1,26 @@
+package client
+
+import "fmt"
+
+// ControllerError wraps a failed call to a jiva controller endpoint,
+// capturing the endpoint that was contacted, the HTTP status returned (if
+// any), the raw response body, and the underlying transport error so
+// callers can tell a dead controller apart from a controller that
+// rejected the request.
+type ControllerError struct {
+	Endpoint   string
+	StatusCode int
+	Body       []byte
+	Err        error
+}
+
+func (e *ControllerError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("controller %s: %v", e.Endpoint, e.Err)
+	}
+	return fmt.Sprintf("controller %s: unexpected response %d: %s", e.Endpoint, e.StatusCode, e.Body)
+}
+
+func (e *ControllerError) Unwrap() error {
+	return e.Err
+}
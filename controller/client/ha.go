@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Policy selects how a multi-endpoint ControllerClient spreads requests
+// across its controllers.
+type Policy int
+
+const (
+	// PolicySequential tries endpoints one at a time, in order, falling
+	// back to the next on connection errors or 5xx responses. Used for
+	// writes, where only one controller should ever apply the change.
+	PolicySequential Policy = iota
+	// PolicyParallelRead races GET requests against every healthy endpoint
+	// and returns the first successful response. Only affects read-only
+	// calls; writes always use PolicySequential semantics.
+	PolicyParallelRead
+)
+
+const defaultProbeInterval = 10 * time.Second
+
+// NewControllerClientHA builds a ControllerClient backed by several
+// controller endpoints for HA jiva deployments. Endpoints are tried in the
+// order given (or raced, for reads, under PolicyParallelRead); an endpoint
+// that errors or returns 5xx is marked unhealthy and skipped until a
+// background probe confirms it has recovered.
+func NewControllerClientHA(controllers []string, policy Policy) *ControllerClient {
+	endpoints := make([]*controllerEndpoint, 0, len(controllers))
+	for _, controller := range controllers {
+		endpoints = append(endpoints, &controllerEndpoint{base: normalizeControllerURL(controller), healthy: true})
+	}
+
+	httpClient := defaultHTTPClient()
+	c := &ControllerClient{
+		endpoints:      endpoints,
+		policy:         policy,
+		httpClient:     httpClient,
+		streamClient:   streamHTTPClient(httpClient.Transport),
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		probeInterval:  defaultProbeInterval,
+		stopProbe:      make(chan struct{}),
+		metrics:        defaultClientMetrics(),
+		tracer:         defaultTracer(),
+		propagator:     defaultPropagator(),
+	}
+	go c.probeLoop()
+	return c
+}
+
+// Close stops the background health probe started by NewControllerClientHA.
+// It is a no-op for a client built with NewControllerClient, which starts
+// no probe.
+func (c *ControllerClient) Close() {
+	if c.stopProbe == nil {
+		return
+	}
+	select {
+	case <-c.stopProbe:
+	default:
+		close(c.stopProbe)
+	}
+}
+
+func (c *ControllerClient) probeLoop() {
+	ticker := time.NewTicker(c.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopProbe:
+			return
+		case <-ticker.C:
+			c.probeUnhealthy()
+		}
+	}
+}
+
+// probeUnhealthy re-checks every endpoint currently marked unhealthy with a
+// GET against /volumes, the same endpoint jivactl-style tools already poll
+// to discover the volume, and marks it healthy again once it responds.
+func (c *ControllerClient) probeUnhealthy() {
+	c.mu.Lock()
+	var unhealthy []*controllerEndpoint
+	for _, ep := range c.endpoints {
+		if !ep.healthy {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, ep := range unhealthy {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+		httpResp, err := c.sendWithRetry(ctx, "GET", ep.base+"/volumes", nil)
+		cancel()
+		if err != nil {
+			continue
+		}
+		httpResp.Body.Close()
+		if httpResp.StatusCode < 500 {
+			c.setHealthy(ep, true)
+		}
+	}
+}
+
+// MultiError aggregates one ControllerError per endpoint that failed, so a
+// caller can see exactly which controllers were tried and why each one was
+// rejected instead of only the last error in the chain.
+type MultiError []*ControllerError
+
+func (m MultiError) Error() string {
+	parts := make([]string, 0, len(m))
+	for _, e := range m {
+		parts = append(parts, e.Error())
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (m MultiError) orNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openebs/jiva/controller/rest"
+)
+
+// TailOptions configures TailJournal.
+type TailOptions struct {
+	// Limit caps how many historical entries are replayed before
+	// streaming live ones; zero means the controller's default. A
+	// reconnect that resumes via Last-Event-ID is not capped.
+	Limit int
+}
+
+// TailJournal streams journal entries from the controller over SSE,
+// giving operators kubectl-logs-f-style visibility into replica add/remove,
+// snapshot, and rebuild events without polling ListJournal. It reconnects
+// with exponential backoff on transient failures, resuming from the last
+// entry seen via the Last-Event-ID header, until ctx is done, at which
+// point both returned channels are closed.
+func (c *ControllerClient) TailJournal(ctx context.Context, opts TailOptions) (<-chan rest.JournalEntry, <-chan error) {
+	entries := make(chan rest.JournalEntry)
+	errs := make(chan error, 1)
+
+	go c.tailJournalLoop(ctx, opts, entries, errs)
+
+	return entries, errs
+}
+
+func (c *ControllerClient) tailJournalLoop(ctx context.Context, opts TailOptions, entries chan<- rest.JournalEntry, errs chan<- error) {
+	defer close(entries)
+	defer close(errs)
+
+	var lastEventID string
+	delay := c.retryBaseDelay
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := c.streamJournalOnce(ctx, opts, &lastEventID, entries)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			select {
+			case errs <- err:
+			default:
+			}
+			if delay < defaultRequestTimeout {
+				delay *= 2
+			}
+		} else {
+			delay = c.retryBaseDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// streamJournalOnce opens one SSE connection to /journal/stream and reads
+// from it until the connection ends, returning nil on a clean server-side
+// close (which the caller will treat the same as any other disconnect and
+// reconnect for).
+func (c *ControllerClient) streamJournalOnce(ctx context.Context, opts TailOptions, lastEventID *string, entries chan<- rest.JournalEntry) error {
+	candidates := c.candidateEndpoints()
+	if len(candidates) == 0 {
+		return errors.New("no controller endpoints configured")
+	}
+
+	path := "/journal/stream"
+	if opts.Limit > 0 {
+		path += "?limit=" + strconv.Itoa(opts.Limit)
+	}
+
+	var lastErr error
+	for _, ep := range candidates {
+		url := ep.base + path
+
+		httpReq, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return err
+		}
+		httpReq = httpReq.WithContext(ctx)
+		httpReq.Header.Set("Accept", "text/event-stream")
+		if *lastEventID != "" {
+			httpReq.Header.Set("Last-Event-ID", *lastEventID)
+		}
+		if c.authProvider != nil {
+			if err := c.authProvider.Authenticate(httpReq, nil); err != nil {
+				return err
+			}
+		}
+
+		httpResp, err := c.streamClient.Do(httpReq)
+		if err != nil {
+			c.setHealthy(ep, false)
+			lastErr = &ControllerError{Endpoint: ep.base, Err: err}
+			continue
+		}
+		if httpResp.StatusCode >= 300 {
+			content, _ := ioutil.ReadAll(httpResp.Body)
+			httpResp.Body.Close()
+			c.setHealthy(ep, false)
+			lastErr = &ControllerError{Endpoint: ep.base, StatusCode: httpResp.StatusCode, Body: content}
+			continue
+		}
+
+		return readJournalSSE(httpResp.Body, lastEventID, entries)
+	}
+
+	return lastErr
+}
+
+// readJournalSSE parses "id:"/"data:" SSE frames from body as JournalEntry
+// JSON until the stream ends.
+func readJournalSSE(body io.ReadCloser, lastEventID *string, entries chan<- rest.JournalEntry) error {
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var dataLines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(dataLines) == 0 {
+				continue
+			}
+			var entry rest.JournalEntry
+			if err := json.Unmarshal([]byte(strings.Join(dataLines, "\n")), &entry); err == nil {
+				entries <- entry
+			}
+			dataLines = nil
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "id:"):
+			*lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jiva_client_requests_total",
+			Help: "Total ControllerClient requests, by method, path, and response status.",
+		}, []string{"method", "path", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "jiva_client_request_duration_seconds",
+			Help:    "ControllerClient request duration in seconds, by method and path.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+	}
+	reg.MustRegister(m.requestsTotal, m.requestDuration)
+	return m
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetricsInst *metrics
+
+	registeredMetricsMu sync.Mutex
+	registeredMetrics   = make(map[prometheus.Registerer]*metrics)
+)
+
+// defaultClientMetrics returns the package's default metrics, registered
+// against prometheus.DefaultRegisterer the first time any ControllerClient
+// needs them. Use WithRegisterer to register a client's metrics against a
+// different registry instead.
+func defaultClientMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetricsInst = newMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetricsInst
+}
+
+// WithRegisterer registers the client's Prometheus metrics against reg
+// instead of the default registry, so callers running several jiva clients
+// in one process (or under test) can keep their metrics separate. Metrics
+// are registered against reg at most once, so multiple ControllerClients
+// sharing the same registry reuse the same collectors instead of panicking
+// on a duplicate registration.
+func (c *ControllerClient) WithRegisterer(reg prometheus.Registerer) *ControllerClient {
+	if reg == prometheus.DefaultRegisterer {
+		c.metrics = defaultClientMetrics()
+		return c
+	}
+
+	registeredMetricsMu.Lock()
+	defer registeredMetricsMu.Unlock()
+
+	m, ok := registeredMetrics[reg]
+	if !ok {
+		m = newMetrics(reg)
+		registeredMetrics[reg] = m
+	}
+	c.metrics = m
+	return c
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/openebs/jiva/controller/client"
+
+func defaultTracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func defaultPropagator() propagation.TextMapPropagator {
+	return otel.GetTextMapPropagator()
+}
+
+// metricPath collapses a request path into a label-safe route name: a
+// plain REST path is used as-is, while an opaque action URL taken from a
+// rest.Volume/rest.Replica Links/Actions map (which embeds a host and
+// address) collapses to "action" to keep label cardinality bounded.
+func metricPath(path string) string {
+	if strings.HasPrefix(path, "http") {
+		return "action"
+	}
+	return path
+}
+
+// startSpan begins the span covering one logical ControllerClient call —
+// every endpoint attempt it makes, and its final decode — with its parent
+// taken from ctx so a caller that threads a request-scoped context through
+// gets an end-to-end trace.
+func (c *ControllerClient) startSpan(ctx context.Context, method, path string) (context.Context, trace.Span, string) {
+	label := metricPath(path)
+	ctx, span := c.tracer.Start(ctx, method+" "+label, trace.WithAttributes(
+		attribute.String("http.method", method),
+		attribute.String("jiva.controller.path", label),
+	))
+	return ctx, span, label
+}
+
+// finishSpanError tags span with the error class a caller should look at
+// when root-causing rebuild storms or snapshot latency regressions.
+func finishSpanError(span trace.Span, errorClass string, err error) {
+	if err == nil {
+		return
+	}
+	span.SetAttributes(attribute.String("controller.error_class", errorClass))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// errorClassFor maps an HTTP status to the error class finishSpanError
+// tags the span with, so a 4xx (the caller's request was rejected) is
+// never confused with a 5xx (the controller itself failed) when
+// root-causing from Grafana/Jaeger.
+func errorClassFor(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return "controller.5xx"
+	case statusCode >= 400:
+		return "controller.4xx"
+	default:
+		return "controller.error"
+	}
+}
+
+func (c *ControllerClient) recordMetrics(method, path, status string, start time.Time) {
+	c.metrics.requestsTotal.WithLabelValues(method, path, status).Inc()
+	c.metrics.requestDuration.WithLabelValues(method, path).Observe(time.Since(start).Seconds())
+}
+
+// decodeResponse reads and decodes httpResp's body, tagging span with an
+// error class on failure, and returns the HTTP status (for metrics)
+// alongside any error.
+func decodeResponse(span trace.Span, endpoint string, httpResp *http.Response, resp interface{}) (string, error) {
+	defer httpResp.Body.Close()
+	status := strconv.Itoa(httpResp.StatusCode)
+
+	content, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		finishSpanError(span, "decode.error", err)
+		return status, &ControllerError{Endpoint: endpoint, StatusCode: httpResp.StatusCode, Err: err}
+	}
+
+	if httpResp.StatusCode >= 300 {
+		cerr := &ControllerError{Endpoint: endpoint, StatusCode: httpResp.StatusCode, Body: content}
+		finishSpanError(span, errorClassFor(httpResp.StatusCode), cerr)
+		return status, cerr
+	}
+
+	if resp == nil {
+		return status, nil
+	}
+
+	if err := json.Unmarshal(content, resp); err != nil {
+		finishSpanError(span, "decode.error", err)
+		return status, err
+	}
+	return status, nil
+}
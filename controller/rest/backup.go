@@ -0,0 +1,32 @@
+package rest
+
+// BackupInput is the request body for POST /backups: take a backup of
+// snapshot Name and ship it to the target named by Dest (e.g.
+// "s3://bucket/prefix" or "nfs://host/export").
+type BackupInput struct {
+	Name string `json:"name"`
+	Dest string `json:"dest"`
+}
+
+// BackupStatus reports the state of a backup. It is returned from
+// POST /backups and GET /backups/{id}, and appears in the collection
+// returned by GET /backups.
+type BackupStatus struct {
+	Id         string `json:"id"`
+	SnapshotId string `json:"snapshotId"`
+	Dest       string `json:"dest"`
+	State      string `json:"state"`
+	Progress   int    `json:"progress"`
+	Size       int64  `json:"size"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BackupCollection is returned from GET /backups.
+type BackupCollection struct {
+	Data []BackupStatus `json:"data"`
+}
+
+// RestoreInput is the request body for POST /backups/{id}/restore.
+type RestoreInput struct {
+	BackupURL string `json:"backupURL"`
+}
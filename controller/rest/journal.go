@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JournalEntry is one event from the controller's journal: a replica
+// add/remove, snapshot, or rebuild event.
+type JournalEntry struct {
+	Id        string    `json:"id"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JournalRingBuffer is a fixed-size in-memory buffer of journal entries
+// that TailJournal subscribers stream over SSE instead of polling
+// ListJournal. Entries are assigned a monotonically increasing id used as
+// the SSE event id, so a reconnecting client can resume with Last-Event-ID.
+type JournalRingBuffer struct {
+	mu          sync.Mutex
+	entries     []JournalEntry
+	next        int
+	filled      bool
+	seq         int64
+	subscribers map[chan JournalEntry]struct{}
+}
+
+func NewJournalRingBuffer(size int) *JournalRingBuffer {
+	return &JournalRingBuffer{
+		entries:     make([]JournalEntry, size),
+		subscribers: make(map[chan JournalEntry]struct{}),
+	}
+}
+
+// Append adds entry to the buffer, assigns it the next sequence id, and
+// pushes it to every current subscriber.
+func (b *JournalRingBuffer) Append(entry JournalEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	entry.Id = strconv.FormatInt(b.seq, 10)
+
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.filled = true
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub <- entry:
+		default: // slow subscriber; drop rather than block the writer
+		}
+	}
+}
+
+// Since returns every buffered entry with an id greater than afterID, in
+// the order they were appended.
+func (b *JournalRingBuffer) Since(afterID int64) []JournalEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.since(afterID)
+}
+
+// since is Since without locking; callers must hold b.mu.
+func (b *JournalRingBuffer) since(afterID int64) []JournalEntry {
+	ordered := make([]JournalEntry, 0, len(b.entries))
+	if b.filled {
+		ordered = append(ordered, b.entries[b.next:]...)
+	}
+	ordered = append(ordered, b.entries[:b.next]...)
+
+	result := make([]JournalEntry, 0, len(ordered))
+	for _, e := range ordered {
+		if id, err := strconv.ParseInt(e.Id, 10, 64); err == nil && id > afterID {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// subscribeFrom atomically takes the backlog since afterID and registers a
+// subscriber channel for entries appended afterwards, so a caller can
+// never see an entry from both the backlog and the live channel.
+func (b *JournalRingBuffer) subscribeFrom(afterID int64) ([]JournalEntry, chan JournalEntry, func()) {
+	b.mu.Lock()
+	backlog := b.since(afterID)
+	ch := make(chan JournalEntry, 16)
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+		close(ch)
+	}
+	return backlog, ch, unsubscribe
+}
+
+// ServeHTTP implements the /journal/stream endpoint: it replays anything
+// newer than the request's Last-Event-ID header, then streams new entries
+// as they're appended until the client disconnects.
+func (b *JournalRingBuffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	var afterID int64
+	if v := req.Header.Get("Last-Event-ID"); v != "" {
+		afterID, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	backlog, sub, unsubscribe := b.subscribeFrom(afterID)
+	defer unsubscribe()
+
+	// A "limit" query parameter caps the replayed history on a fresh
+	// connection; a resuming client (Last-Event-ID set) gets its full gap.
+	if limit, err := strconv.Atoi(req.URL.Query().Get("limit")); err == nil && limit > 0 && afterID == 0 && len(backlog) > limit {
+		backlog = backlog[len(backlog)-limit:]
+	}
+
+	for _, entry := range backlog {
+		writeSSE(w, entry)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case entry, ok := <-sub:
+			if !ok {
+				return
+			}
+			writeSSE(w, entry)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSE(w io.Writer, entry JournalEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", entry.Id, data)
+}